@@ -0,0 +1,28 @@
+// Command qq tails the qq.log file that the qq package writes to -- a
+// portable equivalent of `tail -f /tmp/qq.log` for platforms (Windows,
+// mainly) where that's awkward.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/y0ssar1an/q"
+)
+
+func main() {
+	file := flag.String("file", qq.LogFile, "path to the qq log file to tail")
+	flag.Parse()
+	qq.LogFile = *file
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := qq.Tail(ctx, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "qq:", err)
+		os.Exit(1)
+	}
+}
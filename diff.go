@@ -0,0 +1,182 @@
+package qq
+
+import (
+	"container/list"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// diffCacheSize bounds the number of distinct (call site, arg name) entries
+// tracked for diffing, so a long-running process doesn't leak memory over
+// call sites that only ever fire once.
+const diffCacheSize = 256
+
+// diffCache is an LRU of the last-seen %#v rendering for each (call site,
+// arg name) pair LogDiff has seen.
+type diffCache struct {
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type diffEntry struct {
+	key   string
+	value string
+}
+
+func newDiffCache() *diffCache {
+	return &diffCache{ll: list.New(), items: map[string]*list.Element{}}
+}
+
+// swap records value under key and returns the previously recorded value
+// for key, if any, evicting the least recently used entry if the cache is
+// now over diffCacheSize.
+func (c *diffCache) swap(key, value string) (prev string, had bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*diffEntry)
+		prev, had = e.value, true
+		e.value = value
+		c.ll.MoveToFront(el)
+		return prev, had
+	}
+
+	c.items[key] = c.ll.PushFront(&diffEntry{key: key, value: value})
+	if c.ll.Len() > diffCacheSize {
+		oldest := c.ll.Remove(c.ll.Back()).(*diffEntry)
+		delete(c.items, oldest.key)
+	}
+	return "", false
+}
+
+// globalDiffCache backs every LogDiff call (and every Log call made under
+// QQ_DIFF=1) in the process.
+var globalDiffCache = newDiffCache()
+
+// diffEnabled reports whether QQ_DIFF=1 is set, enabling LogDiff's
+// behavior for plain Log() calls too.
+func diffEnabled() bool {
+	return os.Getenv("QQ_DIFF") == "1"
+}
+
+// formatArgsDiff is formatArgs' counterpart for LogDiff: instead of always
+// rendering the full %#v of each argument, it diffs against the last
+// rendering seen for that (file:line:index, arg name) and prints the
+// change, "(unchanged)", or the full value the first time it's seen.
+//
+// dirs is applied the same way formatArgs applies it, and *before*
+// diffing and caching -- a qq:redact argument must never have its raw
+// value land in globalDiffCache, let alone printed.
+func formatArgsDiff(file string, line int, names []string, dirs []directive, values []interface{}) []interface{} {
+	formatted := make([]interface{}, len(values))
+	for i, v := range values {
+		name := names[i]
+		var d directive
+		if i < len(dirs) {
+			d = dirs[i]
+		}
+		if d.Label != "" {
+			name = d.Label
+		}
+
+		key := fmt.Sprintf("%s:%d:%d:%s", file, line, i, names[i])
+		cur := renderValue(v, d)
+		prev, had := globalDiffCache.swap(key, cur)
+
+		var rendered string
+		switch {
+		case d.Redact:
+			// a redacted value is always "***"; there's nothing to diff.
+			rendered = colorize(cur, cyan)
+		case !had:
+			rendered = colorize(cur, cyan)
+		case prev == cur:
+			rendered = colorize("(unchanged)", yellow)
+		default:
+			rendered = colorizeDiff(prev, cur)
+		}
+
+		if name == "" {
+			formatted[i] = rendered
+		} else {
+			formatted[i] = fmt.Sprintf("%s=%s", colorize(name, bold), rendered)
+		}
+	}
+	return formatted
+}
+
+// diffOp is one line of a diffLines result: kept (' '), removed ('-'), or
+// added ('+').
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// diffLines computes a line-level diff between a and b via a longest
+// common subsequence, a small Myers-style differ good enough for the
+// struct/map dumps LogDiff compares without pulling in a diff library.
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+// colorizeDiff renders a unified diff of prev -> cur, one line per diffOp,
+// coloring removed lines red and added lines green.
+func colorizeDiff(prev, cur string) string {
+	ops := diffLines(strings.Split(prev, "\n"), strings.Split(cur, "\n"))
+	lines := make([]string, len(ops))
+	for i, op := range ops {
+		switch op.kind {
+		case '-':
+			lines[i] = colorize("-"+op.line, red)
+		case '+':
+			lines[i] = colorize("+"+op.line, green)
+		default:
+			lines[i] = " " + op.line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
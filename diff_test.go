@@ -0,0 +1,113 @@
+package qq
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// reconstruct rebuilds the "from" and "to" sides of a diffLines result, to
+// check the diff is actually a valid edit script rather than just
+// eyeballing individual cases.
+func reconstruct(ops []diffOp) (from, to []string) {
+	for _, op := range ops {
+		switch op.kind {
+		case ' ':
+			from = append(from, op.line)
+			to = append(to, op.line)
+		case '-':
+			from = append(from, op.line)
+		case '+':
+			to = append(to, op.line)
+		}
+	}
+	return from, to
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+	}{
+		{"identical", []string{"a", "b", "c"}, []string{"a", "b", "c"}},
+		{"all removed", []string{"a", "b"}, nil},
+		{"all added", nil, []string{"a", "b"}},
+		{"one line changed", []string{"a", "b", "c"}, []string{"a", "x", "c"}},
+		{"insertion in the middle", []string{"a", "c"}, []string{"a", "b", "c"}},
+		{"both empty", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := diffLines(tt.a, tt.b)
+			from, to := reconstruct(ops)
+			if !strEqual(from, tt.a) {
+				t.Errorf("reconstructed from-side = %v, want %v", from, tt.a)
+			}
+			if !strEqual(to, tt.b) {
+				t.Errorf("reconstructed to-side = %v, want %v", to, tt.b)
+			}
+		})
+	}
+}
+
+func strEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestColorizeDiff(t *testing.T) {
+	out := colorizeDiff("a\nb", "a\nc")
+	if !strings.Contains(out, "-b") {
+		t.Errorf("diff output %q missing removed line %q", out, "-b")
+	}
+	if !strings.Contains(out, "+c") {
+		t.Errorf("diff output %q missing added line %q", out, "+c")
+	}
+}
+
+func TestDiffCacheSwap(t *testing.T) {
+	c := newDiffCache()
+
+	if _, had := c.swap("k", "v1"); had {
+		t.Fatal("first swap for a key should report had=false")
+	}
+
+	prev, had := c.swap("k", "v2")
+	if !had || prev != "v1" {
+		t.Fatalf("second swap = (%q, %v), want (%q, true)", prev, had, "v1")
+	}
+
+	prev, had = c.swap("k", "v2")
+	if !had || prev != "v2" {
+		t.Fatalf("third swap = (%q, %v), want (%q, true)", prev, had, "v2")
+	}
+}
+
+func TestDiffCacheEviction(t *testing.T) {
+	c := newDiffCache()
+
+	for i := 0; i < diffCacheSize; i++ {
+		c.swap(fmt.Sprintf("key%d", i), "v")
+	}
+	// one more insert should evict key0, the least recently used entry,
+	// without disturbing anything else.
+	c.swap(fmt.Sprintf("key%d", diffCacheSize), "v")
+
+	if c.ll.Len() != diffCacheSize {
+		t.Fatalf("cache has %d entries, want %d", c.ll.Len(), diffCacheSize)
+	}
+	if _, ok := c.items["key0"]; ok {
+		t.Error("least recently used entry should have been evicted")
+	}
+	if _, ok := c.items["key1"]; !ok {
+		t.Error("more recently used entry should still be cached")
+	}
+}
@@ -0,0 +1,115 @@
+package qq
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// directive holds the rendering overrides that apply to one particular
+// argument, after a callDirective has been resolved against that
+// argument's index. The zero value means "no override." qq:skip is a
+// call-level directive, not a per-argument one -- see callSite.skip.
+type directive struct {
+	Redact bool   // qq:redact -- render the value as "***"
+	Hex    bool   // qq:hex -- render the value with %#x instead of %#v
+	JSON   bool   // qq:json -- render the value as json.Marshal output
+	Pretty bool   // qq:pretty -- render the value as json.MarshalIndent output
+	Label  string // qq:label=name -- use name as the displayed label
+}
+
+// argTarget describes which argument indices a directive applies to.
+// `qq:redact` with no "=value" applies to every argument; `qq:redact=1` or
+// `qq:redact=0,2` applies only to the listed (0-based) indices, so a
+// single sensitive argument in a multi-arg call can be targeted without
+// affecting its neighbors.
+type argTarget struct {
+	all     bool
+	indices map[int]bool
+}
+
+func (t argTarget) applies(i int) bool {
+	return t.all || t.indices[i]
+}
+
+// parseTarget parses a directive's value as a comma-separated list of
+// argument indices. An empty value means "every argument."
+func parseTarget(s string) argTarget {
+	if s == "" {
+		return argTarget{all: true}
+	}
+	indices := map[int]bool{}
+	for _, f := range strings.Split(s, ",") {
+		if n, err := strconv.Atoi(strings.TrimSpace(f)); err == nil {
+			indices[n] = true
+		}
+	}
+	return argTarget{indices: indices}
+}
+
+// callDirective is the raw directive parsed from a call site's comments,
+// before it's resolved into a per-argument directive for each argument.
+type callDirective struct {
+	Skip   bool
+	Redact argTarget
+	Hex    argTarget
+	JSON   argTarget
+	Pretty argTarget
+	Label  string
+}
+
+// directiveRe matches a single `qq:name` or `qq:name=value` directive
+// token anywhere inside a comment.
+var directiveRe = regexp.MustCompile(`qq:(\w+)(?:=(\S+))?`)
+
+// parseDirective scans text (the combined text of one or more comment
+// lines) for `qq:` directives and returns the callDirective they describe.
+// Unrecognized or malformed directives are ignored.
+func parseDirective(text string) callDirective {
+	var d callDirective
+	for _, m := range directiveRe.FindAllStringSubmatch(text, -1) {
+		target := parseTarget(m[2])
+		switch m[1] {
+		case "skip":
+			d.Skip = true
+		case "redact":
+			d.Redact = target
+		case "hex":
+			d.Hex = target
+		case "json":
+			d.JSON = target
+		case "pretty":
+			d.Pretty = target
+		case "label":
+			d.Label = m[2]
+		}
+	}
+	return d
+}
+
+// renderValue renders v the way qq normally would (%#v), unless d requests
+// an override.
+func renderValue(v interface{}, d directive) string {
+	switch {
+	case d.Redact:
+		return "***"
+	case d.JSON:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%#v", v)
+		}
+		return string(b)
+	case d.Pretty:
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Sprintf("%#v", v)
+		}
+		return string(b)
+	case d.Hex:
+		return fmt.Sprintf("%#x", v)
+	default:
+		return fmt.Sprintf("%#v", v)
+	}
+}
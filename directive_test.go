@@ -0,0 +1,88 @@
+package qq
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDirective(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want callDirective
+	}{
+		{
+			name: "skip",
+			text: "qq:skip",
+			want: callDirective{Skip: true},
+		},
+		{
+			name: "redact with no target applies to every arg",
+			text: "qq:redact",
+			want: callDirective{Redact: argTarget{all: true}},
+		},
+		{
+			name: "redact targets a single index",
+			text: "qq:redact=1",
+			want: callDirective{Redact: argTarget{indices: map[int]bool{1: true}}},
+		},
+		{
+			name: "redact targets multiple indices",
+			text: "qq:redact=0,2",
+			want: callDirective{Redact: argTarget{indices: map[int]bool{0: true, 2: true}}},
+		},
+		{
+			name: "label",
+			text: "qq:label=apiKey",
+			want: callDirective{Label: "apiKey"},
+		},
+		{
+			name: "multiple directives in one comment",
+			text: "qq:redact=1 qq:hex=0",
+			want: callDirective{
+				Redact: argTarget{indices: map[int]bool{1: true}},
+				Hex:    argTarget{indices: map[int]bool{0: true}},
+			},
+		},
+		{
+			name: "unrecognized directive is ignored",
+			text: "qq:bogus see https://example.com for details",
+			want: callDirective{},
+		},
+		{
+			name: "plain comment has no directives",
+			text: "just a regular comment",
+			want: callDirective{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseDirective(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDirective(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArgTargetApplies(t *testing.T) {
+	tests := []struct {
+		name   string
+		target argTarget
+		index  int
+		want   bool
+	}{
+		{"all applies to any index", argTarget{all: true}, 5, true},
+		{"zero value applies to nothing", argTarget{}, 0, false},
+		{"matching index applies", argTarget{indices: map[int]bool{1: true}}, 1, true},
+		{"non-matching index doesn't apply", argTarget{indices: map[int]bool{1: true}}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.target.applies(tt.index); got != tt.want {
+				t.Errorf("applies(%d) = %v, want %v", tt.index, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package qq
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Encoder renders an Event as output with no trailing newline; sinks add one
+// if they need it. The output is a single line, except from the human
+// encoder when a qq:pretty directive applies: the indented value then spans
+// several lines.
+type Encoder interface {
+	Encode(Event) []byte
+}
+
+// parseEncoder parses a QQ_FORMAT value, defaulting to the human encoder --
+// qq's original colorized output -- if s is empty or unrecognized.
+func parseEncoder(s string) Encoder {
+	switch s {
+	case "logfmt":
+		return logfmtEncoder{}
+	case "json":
+		return jsonEncoder{}
+	default:
+		return humanEncoder{}
+	}
+}
+
+// humanEncoder reproduces the colorized "name=value" output Log has always
+// produced.
+type humanEncoder struct{}
+
+func (humanEncoder) Encode(e Event) []byte {
+	parts := make([]string, len(e.Args))
+	for i, a := range e.Args {
+		name := a.Name
+		if a.Dir.Label != "" {
+			name = a.Dir.Label
+		}
+
+		val := colorize(renderValue(a.Value, a.Dir), cyan)
+		if name == "" {
+			parts[i] = val
+		} else {
+			parts[i] = fmt.Sprintf("%s=%s", colorize(name, bold), val)
+		}
+	}
+
+	p := fmt.Sprintf("[%s %s:%d %s] ", e.Time.Format("15:04:05"), filepath.Base(e.File), e.Line, e.Func)
+	return []byte(p + strings.Join(parts, " "))
+}
+
+// logfmtEncoder renders an Event in the key=value style popularized by
+// go-kit and Heroku's logfmt.
+type logfmtEncoder struct{}
+
+func (logfmtEncoder) Encode(e Event) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "time=%s level=%s file=%s line=%d func=%s",
+		e.Time.Format(time.RFC3339), e.Level, filepath.Base(e.File), e.Line, e.Func)
+
+	for i, a := range e.Args {
+		name := a.Name
+		if a.Dir.Label != "" {
+			name = a.Dir.Label
+		}
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		fmt.Fprintf(&buf, " %s=%q", name, renderValue(a.Value, a.Dir))
+	}
+	return buf.Bytes()
+}
+
+// jsonEncoder renders an Event as a single JSON object.
+type jsonEncoder struct{}
+
+type jsonEvent struct {
+	Time  time.Time `json:"time"`
+	Level string    `json:"level"`
+	File  string    `json:"file"`
+	Line  int       `json:"line"`
+	Func  string    `json:"func"`
+	Args  []jsonArg `json:"args"`
+}
+
+// jsonArg is one Event.Arg as it appears in the json encoder's output. It's
+// a slice element rather than a map value so that argument order is
+// preserved and two arguments that happen to render to the same name
+// (duplicate expressions, colliding qq:label overrides) don't clobber each
+// other.
+type jsonArg struct {
+	Name      string `json:"name"`
+	Value     string `json:"value"`
+	IsLiteral bool   `json:"isLiteral"`
+}
+
+func (jsonEncoder) Encode(e Event) []byte {
+	args := make([]jsonArg, len(e.Args))
+	for i, a := range e.Args {
+		name := a.Name
+		if a.Dir.Label != "" {
+			name = a.Dir.Label
+		}
+		args[i] = jsonArg{
+			Name:      name,
+			Value:     renderValue(a.Value, a.Dir),
+			IsLiteral: name == "",
+		}
+	}
+
+	b, err := json.Marshal(jsonEvent{
+		Time:  e.Time,
+		Level: e.Level.String(),
+		File:  filepath.Base(e.File),
+		Line:  e.Line,
+		Func:  e.Func,
+		Args:  args,
+	})
+	if err != nil {
+		return []byte(fmt.Sprintf(`{"error":%q}`, err.Error()))
+	}
+	return b
+}
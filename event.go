@@ -0,0 +1,26 @@
+package qq
+
+import "time"
+
+// Arg is one argument to a logging call. Name is empty when the argument
+// was a literal, the same distinction formatArgs has always drawn for the
+// human output.
+type Arg struct {
+	Name      string
+	Value     interface{}
+	IsLiteral bool
+	Dir       directive // any `qq:` directive attached to the call site
+}
+
+// Event is a single logging call, captured independently of how it will be
+// encoded or where it will end up. Debug, Info, Warn, and Error build one
+// of these per call and hand it to every configured Sink.
+type Event struct {
+	Time  time.Time
+	PC    uintptr
+	File  string
+	Line  int
+	Func  string
+	Level Level
+	Args  []Arg
+}
@@ -0,0 +1,222 @@
+package qq
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// qqImportPath is the import path this package is known by, regardless of
+// the local alias a caller imports it under, e.g. `import q "github.com/y0ssar1an/q"`.
+const qqImportPath = "github.com/y0ssar1an/q"
+
+// callSite is everything argNames needs to report about a single qq call:
+// the source text of each argument, any per-argument `qq:` directives, and
+// whether qq:skip was attached to the call. skip is tracked independently
+// of dirs -- a zero-argument call has an empty dirs slice with nowhere to
+// carry it, but qq:skip must still take effect.
+type callSite struct {
+	names []string
+	dirs  []directive
+	skip  bool
+}
+
+// fileCallInfo is the type-checked result of a single source file, cached
+// across calls so repeated qq.Log() calls in the same file -- the common
+// case in a hot loop -- don't re-run the packages loader every time.
+type fileCallInfo struct {
+	modTime time.Time
+	calls   map[int]callSite // line number -> call site info for the qq call on that line
+}
+
+var (
+	callCacheMu sync.Mutex
+	callCache   = map[string]*fileCallInfo{}
+)
+
+// argNames finds the qq call at the given filename/line number and returns
+// its arguments as a slice of strings, a parallel slice of any `qq:`
+// directives attached to the call, and whether the call was marked
+// qq:skip. If the argument is a literal, argNames will return an empty
+// string at the index position of that argument. For example,
+// qq.Log(ip, port, 5432) would return []string{"ip", "port", ""}.
+//
+// Unlike a plain AST walk, argNames type-checks the caller's package so it
+// can resolve exactly which calls are to qq's logging funcs -- including
+// through import aliases, though not through a function variable holding
+// one of them -- and so it can label any
+// argument expression, not just the handful of ast.Expr kinds a textual
+// switch happens to know about. err is non-nil if the package containing
+// filename cannot be loaded and type-checked.
+func argNames(filename string, line int) ([]string, []directive, bool, error) {
+	info, err := loadCallInfo(filename)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	site := info.calls[line]
+	return site.names, site.dirs, site.skip, nil
+}
+
+// loadCallInfo loads and type-checks the package containing filename and
+// records every qq call in it, keyed by line number. The result is cached
+// by (filename, mtime) so a process that calls qq.Log() in a loop only
+// pays for the packages.Load once.
+func loadCallInfo(filename string) (*fileCallInfo, error) {
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	callCacheMu.Lock()
+	cached, ok := callCache[filename]
+	callCacheMu.Unlock()
+	if ok && cached.modTime.Equal(fi.ModTime()) {
+		return cached, nil
+	}
+
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+			packages.NeedTypes | packages.NeedTypesInfo,
+		Dir: filepath.Dir(filename),
+	}
+	pkgs, err := packages.Load(cfg, "file="+filename)
+	if err != nil {
+		return nil, err
+	}
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 {
+			return nil, pkg.Errors[0]
+		}
+	}
+
+	info := &fileCallInfo{modTime: fi.ModTime(), calls: map[int]callSite{}}
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Syntax {
+			if pkg.Fset.Position(f.Pos()).Filename != filename {
+				continue
+			}
+			recordCalls(pkg.Fset, pkg.TypesInfo, f, info.calls)
+		}
+	}
+
+	callCacheMu.Lock()
+	callCache[filename] = info
+	callCacheMu.Unlock()
+	return info, nil
+}
+
+// recordCalls walks f and fills calls[line] with the call site info for
+// every call resolved to one of qq's logging funcs.
+func recordCalls(fset *token.FileSet, info *types.Info, f *ast.File, calls map[int]callSite) {
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, is := n.(*ast.CallExpr)
+		if !is {
+			return true
+		}
+		if !isQQCall(info, call) {
+			return true
+		}
+
+		names := make([]string, len(call.Args))
+		for i, arg := range call.Args {
+			names[i] = argName(fset, info, arg)
+		}
+
+		d := directiveForCall(fset, f.Comments, call)
+		dirs := make([]directive, len(call.Args))
+		for i := range dirs {
+			dirs[i] = directive{
+				Redact: d.Redact.applies(i),
+				Hex:    d.Hex.applies(i),
+				JSON:   d.JSON.applies(i),
+				Pretty: d.Pretty.applies(i),
+			}
+		}
+		if d.Label != "" && len(dirs) > 0 {
+			dirs[0].Label = d.Label
+		}
+
+		line := fset.Position(call.End()).Line
+		calls[line] = callSite{names: names, dirs: dirs, skip: d.Skip}
+		return true
+	})
+}
+
+// directiveForCall returns the directive described by any comment attached
+// to call: a trailing comment on the call's last line, or a comment group
+// on the line immediately above the call.
+func directiveForCall(fset *token.FileSet, comments []*ast.CommentGroup, call *ast.CallExpr) callDirective {
+	startLine := fset.Position(call.Pos()).Line
+	endLine := fset.Position(call.End()).Line
+
+	var texts []string
+	for _, cg := range comments {
+		if fset.Position(cg.Pos()).Line == endLine && cg.Pos() > call.End() {
+			texts = append(texts, cg.Text())
+			continue
+		}
+		if fset.Position(cg.End()).Line == startLine-1 {
+			texts = append(texts, cg.Text())
+		}
+	}
+	return parseDirective(strings.Join(texts, " "))
+}
+
+// isQQCall reports whether call resolves, via type information, to one of
+// qq's logging funcs. This catches aliased imports that a textual check on
+// the selector's identifier would miss. A call through a function variable
+// (f := qq.Log; f(x)) is not matched, since f resolves to a *types.Var.
+func isQQCall(info *types.Info, call *ast.CallExpr) bool {
+	var ident *ast.Ident
+	switch fn := call.Fun.(type) {
+	case *ast.SelectorExpr:
+		ident = fn.Sel
+	case *ast.Ident:
+		ident = fn
+	default:
+		return false
+	}
+
+	fn, is := info.Uses[ident].(*types.Func)
+	if !is || fn.Pkg() == nil || fn.Pkg().Path() != qqImportPath {
+		return false
+	}
+	switch fn.Name() {
+	case "Log", "LogDiff", "Debug", "Info", "Warn", "Error":
+		return true
+	}
+	return false
+}
+
+// argName returns the source text of arg, printed with go/format, unless arg
+// is a literal (a basic literal, or an identifier that doesn't resolve to a
+// variable) in which case it returns "". The text is reprinted rather than
+// copied from the file: spacing follows gofmt, and comments inside the
+// expression are not included.
+func argName(fset *token.FileSet, info *types.Info, arg ast.Expr) string {
+	switch a := arg.(type) {
+	case *ast.BasicLit:
+		return ""
+	case *ast.Ident:
+		if _, is := info.Uses[a].(*types.Var); !is {
+			if _, is := info.Defs[a].(*types.Var); !is {
+				return ""
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, arg); err != nil {
+		return ""
+	}
+	return buf.String()
+}
@@ -0,0 +1,53 @@
+package qq
+
+import (
+	"os"
+	"strings"
+)
+
+// Level is the severity of a logged Event, in increasing order of
+// importance.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// String returns the lowercase name of the level, as used by QQ_LEVEL and
+// the logfmt/json encoders.
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// parseLevel parses a QQ_LEVEL value, defaulting to LevelDebug (show
+// everything) if s is empty or unrecognized.
+func parseLevel(s string) Level {
+	switch strings.ToLower(s) {
+	case "info":
+		return LevelInfo
+	case "warn", "warning":
+		return LevelWarn
+	case "error":
+		return LevelError
+	default:
+		return LevelDebug
+	}
+}
+
+// minLevel is the lowest level that will be passed on to the configured
+// sinks. It's read once from QQ_LEVEL at process start.
+var minLevel = parseLevel(os.Getenv("QQ_LEVEL"))
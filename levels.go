@@ -0,0 +1,72 @@
+package qq
+
+import (
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+var (
+	sinksOnce sync.Once
+	sinks     []Sink
+)
+
+func initSinks() {
+	enc := parseEncoder(os.Getenv("QQ_FORMAT"))
+	sinks = parseSinks(os.Getenv("QQ_SINK"), enc)
+}
+
+// Debug logs a at LevelDebug.
+func Debug(a ...interface{}) { leveledLog(LevelDebug, a) }
+
+// Info logs a at LevelInfo.
+func Info(a ...interface{}) { leveledLog(LevelInfo, a) }
+
+// Warn logs a at LevelWarn.
+func Warn(a ...interface{}) { leveledLog(LevelWarn, a) }
+
+// Error logs a at LevelError.
+func Error(a ...interface{}) { leveledLog(LevelError, a) }
+
+// leveledLog builds an Event for a and fans it out to every sink
+// configured via QQ_SINK, unless lvl is below QQ_LEVEL.
+func leveledLog(lvl Level, a []interface{}) {
+	if lvl < minLevel {
+		return
+	}
+	sinksOnce.Do(initSinks)
+
+	e := Event{Time: time.Now(), Level: lvl, Args: toArgs(nil, nil, a)}
+	if pc, file, line, ok := runtime.Caller(2); ok {
+		if names, dirs, skip, err := argNames(file, line); err == nil {
+			if skip {
+				return
+			}
+			e.Args = toArgs(names, dirs, a)
+		}
+		e.PC, e.File, e.Line, e.Func = pc, file, line, runtime.FuncForPC(pc).Name()
+	}
+
+	for _, sink := range sinks {
+		sink.Write(e)
+	}
+}
+
+// toArgs pairs names, directives, and values into Args for the leveled
+// Event path.
+func toArgs(names []string, dirs []directive, values []interface{}) []Arg {
+	args := make([]Arg, len(values))
+	for i, v := range values {
+		var name string
+		if i < len(names) {
+			name = names[i]
+		}
+		var d directive
+		if i < len(dirs) {
+			d = dirs[i]
+		}
+		args[i] = Arg{Name: name, Value: v, IsLiteral: name == "", Dir: d}
+	}
+	return args
+}
@@ -1,12 +1,7 @@
 package qq
 
 import (
-	"bytes"
 	"fmt"
-	"go/ast"
-	"go/parser"
-	"go/printer"
-	"go/token"
 	"log"
 	"os"
 	"path/filepath"
@@ -18,6 +13,8 @@ type color string
 
 const (
 	bold     color = "\033[1m"
+	red      color = "\033[31m"
+	green    color = "\033[32m"
 	yellow   color = "\033[33m"
 	cyan     color = "\033[36m"
 	endColor color = "\033[0m" // ANSI escape code for "reset everything"
@@ -39,12 +36,34 @@ var (
 
 // TODO: function comment here
 func Log(a ...interface{}) {
-	// get info about the func calling qq.Log()
-	pc, file, line, ok := runtime.Caller(1)
+	logCall(a, diffEnabled())
+}
+
+// LogDiff behaves like Log, except instead of dumping the full %#v of each
+// argument on every call, it remembers the last-seen rendering for each
+// (call site, argument name) and prints a colorized diff of what changed --
+// or "(unchanged)" if it didn't -- on every call after the first. This
+// keeps output readable when logging large structs or maps in a hot loop.
+// Set QQ_DIFF=1 to get the same behavior out of plain Log() calls.
+func LogDiff(a ...interface{}) {
+	logCall(a, true)
+}
+
+// logCall is the shared implementation behind Log and LogDiff.
+func logCall(a []interface{}, diff bool) {
+	// get info about the func calling qq.Log()/qq.LogDiff()
+	pc, file, line, ok := runtime.Caller(2)
 	if ok {
-		names, err := argNames(file, line)
+		names, dirs, skip, err := argNames(file, line)
 		if err == nil {
-			a = formatArgs(names, a)
+			if skip {
+				return
+			}
+			if diff {
+				a = formatArgsDiff(file, line, names, dirs, a)
+			} else {
+				a = formatArgs(names, dirs, a)
+			}
 		}
 
 		logger.SetPrefix(prefix(pc, file, line))
@@ -61,91 +80,6 @@ func Log(a ...interface{}) {
 	logger.Println(a...)
 }
 
-// argNames finds the qq.Log() call at the given filename/line number and
-// returns its arguments as a slice of strings. If the argument is a literal,
-// argNames will return an empty string at the index position of that argument.
-// For example, qq.Log(ip, port, 5432) would return []string{"ip", "port", ""}.
-// err will be non-nil if the source text cannot be parsed.
-func argNames(filename string, line int) ([]string, error) {
-	fset := token.NewFileSet()
-	f, err := parser.ParseFile(fset, filename, nil, 0)
-	if err != nil {
-		return nil, err
-	}
-
-	var names []string
-	ast.Inspect(f, func(n ast.Node) bool {
-		call, is := n.(*ast.CallExpr)
-		if !is {
-			return true // visit next node
-		}
-
-		// is a function call, but on wrong line
-		if fset.Position(call.End()).Line != line {
-			return true
-		}
-
-		// is a function call on correct line, but not a qq function
-		if !qqCall(call) {
-			return true
-		}
-
-		for _, arg := range call.Args {
-			names = append(names, argName(arg))
-		}
-		return true
-	})
-
-	return names, nil
-}
-
-// qqCall returns true if the given function call expression is for a qq
-// function, e.g. qq.Log().
-func qqCall(n *ast.CallExpr) bool {
-	sel, is := n.Fun.(*ast.SelectorExpr) // example of SelectorExpr: a.B()
-	if !is {
-		return false
-	}
-
-	ident, is := sel.X.(*ast.Ident) // sel.X is
-	if !is {
-		return false
-	}
-
-	return ident.Name == "qq"
-}
-
-// argName returns the source text of the given argument if it's a variable or
-// an expression. If the argument is something else, like a literal, argName
-// returns an empty string.
-func argName(arg ast.Expr) string {
-	var name string
-	switch a := arg.(type) {
-	case *ast.Ident:
-		if a.Obj.Kind == ast.Var {
-			name = a.Obj.Name
-		}
-	case *ast.BinaryExpr,
-		*ast.CallExpr,
-		*ast.IndexExpr,
-		*ast.KeyValueExpr,
-		*ast.ParenExpr,
-		*ast.SliceExpr,
-		*ast.TypeAssertExpr,
-		*ast.UnaryExpr:
-		name = exprToString(arg)
-	}
-	return name
-}
-
-// exprToString returns the source text underlying the given ast.Expr.
-func exprToString(arg ast.Expr) string {
-	var buf bytes.Buffer
-	fset := token.NewFileSet()
-	printer.Fprint(&buf, fset, arg)
-	return buf.String() // returns empty string if printer fails
-}
-
 // TODO: scrap this prefix and just use the timer value
 func prefix(pc uintptr, file string, line int) string {
 	t := time.Now().Format("15:04:05")
@@ -168,18 +102,29 @@ func openLog() *os.File {
 // argument is a variable or an expression, it will be returned as a
 // name=value string, e.g. "port=443", "3+2=5". Variable names, expressions, and
 // values are colorized using ANSI escape codes.
-func formatArgs(names []string, values []interface{}) []interface{} {
+//
+// dirs carries any `qq:` directives attached to the call site -- qq:redact,
+// qq:hex, qq:json, and qq:pretty override how the value itself is
+// rendered, and qq:label overrides the displayed name. dirs may be nil, in
+// which case no overrides apply.
+func formatArgs(names []string, dirs []directive, values []interface{}) []interface{} {
 	formatted := make([]interface{}, len(values))
 	for i := 0; i < len(values); i++ {
-		val := fmt.Sprintf("%#v", values[i])
-		val = colorize(val, cyan)
+		name := names[i]
+		var d directive
+		if i < len(dirs) {
+			d = dirs[i]
+		}
+		if d.Label != "" {
+			name = d.Label
+		}
 
-		if names[i] == "" {
+		val := colorize(renderValue(values[i], d), cyan)
+		if name == "" {
 			// arg is a literal
 			formatted[i] = val
 		} else {
-			name := colorize(names[i], bold)
-			formatted[i] = fmt.Sprintf("%s=%s", name, val)
+			formatted[i] = fmt.Sprintf("%s=%s", colorize(name, bold), val)
 		}
 	}
 	return formatted
@@ -0,0 +1,105 @@
+package qq
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Sink receives encoded log Events. Implementations must be safe for
+// concurrent use, since Debug/Info/Warn/Error may be called from multiple
+// goroutines.
+type Sink interface {
+	Write(Event)
+}
+
+// writerSink encodes each Event with enc and writes the result, followed by
+// a newline, to w.
+type writerSink struct {
+	mu  sync.Mutex
+	w   io.Writer
+	enc Encoder
+}
+
+func (s *writerSink) Write(e Event) {
+	b := append(s.enc.Encode(e), '\n')
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.w.Write(b)
+}
+
+// fileSink opens path on every write and closes it again afterward,
+// mirroring the open/close-per-call approach openLog has always used for
+// qq.log so the file is never left dangling open.
+type fileSink struct {
+	mu   sync.Mutex
+	path string
+	enc  Encoder
+}
+
+func (s *fileSink) Write(e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "qq: open sink %s: %v\n", s.path, err)
+		return
+	}
+	defer f.Close()
+	f.Write(append(s.enc.Encode(e), '\n'))
+}
+
+// udpSink writes each encoded Event as a single UDP datagram to addr. It
+// drops rather than retries on error, since log shipping shouldn't block
+// the caller.
+type udpSink struct {
+	conn net.Conn
+	enc  Encoder
+}
+
+func newUDPSink(addr string, enc Encoder) (*udpSink, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &udpSink{conn: conn, enc: enc}, nil
+}
+
+func (s *udpSink) Write(e Event) {
+	s.conn.Write(s.enc.Encode(e))
+}
+
+// parseSinks parses a QQ_SINK value -- a comma-separated list of
+// "file:/path", "stderr", or "udp://host:port" -- into concrete Sinks. An
+// empty value sinks to LogFile, matching qq's original behavior.
+func parseSinks(s string, enc Encoder) []Sink {
+	if s == "" {
+		return []Sink{&fileSink{path: LogFile, enc: enc}}
+	}
+
+	var sinks []Sink
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "stderr":
+			sinks = append(sinks, &writerSink{w: os.Stderr, enc: enc})
+		case strings.HasPrefix(part, "file:"):
+			sinks = append(sinks, &fileSink{path: strings.TrimPrefix(part, "file:"), enc: enc})
+		case strings.HasPrefix(part, "udp://"):
+			addr := strings.TrimPrefix(part, "udp://")
+			sink, err := newUDPSink(addr, enc)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "qq: udp sink %s: %v\n", addr, err)
+				continue
+			}
+			sinks = append(sinks, sink)
+		default:
+			fmt.Fprintf(os.Stderr, "qq: unrecognized QQ_SINK entry %q\n", part)
+		}
+	}
+	return sinks
+}
@@ -0,0 +1,141 @@
+package qq
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Tail streams LogFile to out: it writes the file's existing content, then
+// follows appends in real time until ctx is done. It re-opens the file after
+// a rename or removal and rewinds to the start after a truncation, so logrotate rotating qq.log out from under it (or
+// a user running `rm qq.log`) doesn't wedge the tail -- the next write
+// simply picks up the new file. It also reproduces the blank-line group
+// breaks Log writes when more than 2s passes between log calls, since a
+// Sink that didn't originate from Log (see Debug, Info, Warn, Error) won't
+// have written one itself.
+func Tail(ctx context.Context, out io.Writer) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("qq: tail: %w", err)
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(filepath.Dir(LogFile)); err != nil {
+		return fmt.Errorf("qq: tail: %w", err)
+	}
+
+	t := &tailer{out: out}
+	defer t.close()
+
+	if err := t.drain(); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(ev.Name) != filepath.Clean(LogFile) {
+				continue
+			}
+			if ev.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+				t.close()
+				continue
+			}
+			if err := t.drain(); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("qq: tail: %w", err)
+		}
+	}
+}
+
+// tailer tracks an open handle on LogFile across rotations and reproduces
+// Log's 2s group-break behavior based on wall-clock gaps between drains.
+type tailer struct {
+	out      io.Writer
+	f        *os.File
+	r        *bufio.Reader
+	lastRead time.Time
+	wrote    bool
+}
+
+func (t *tailer) open() error {
+	f, err := os.Open(LogFile)
+	if err != nil {
+		return err
+	}
+	t.f = f
+	t.r = bufio.NewReader(f)
+	return nil
+}
+
+func (t *tailer) close() {
+	if t.f != nil {
+		t.f.Close()
+		t.f, t.r = nil, nil
+	}
+}
+
+// drain reads and writes out everything newly appended to LogFile since the
+// last call, (re)opening the file if it isn't open yet and rewinding if the
+// file was truncated out from under us.
+func (t *tailer) drain() error {
+	if t.f == nil {
+		if err := t.open(); err != nil {
+			return err
+		}
+	}
+
+	if fi, err := t.f.Stat(); err == nil {
+		if off, _ := t.f.Seek(0, io.SeekCurrent); off > fi.Size() {
+			t.f.Seek(0, io.SeekStart)
+			t.r.Reset(t.f)
+		}
+	}
+
+	buf := make([]byte, 32*1024)
+	var any bool
+	for {
+		n, err := t.r.Read(buf)
+		if n > 0 {
+			if !any {
+				any = true
+				gap := t.wrote && !t.lastRead.IsZero() && time.Since(t.lastRead) > 2*time.Second
+				// Log() already wrote its own leading blank line into the
+				// file for this same break; only add ours if the content
+				// doesn't already start with one, so breaks aren't doubled.
+				if gap && buf[0] != '\n' {
+					fmt.Fprintln(t.out)
+				}
+			}
+			t.out.Write(buf[:n])
+			t.wrote = true
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if any {
+		t.lastRead = time.Now()
+	}
+	return nil
+}